@@ -0,0 +1,459 @@
+// Copyright 2024 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package certmaker
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	awskmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/go-piv/piv-go/v2/piv"
+)
+
+func TestPkcs11KeyID(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyID   string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare label passes through", keyID: "my-root-key", want: "my-root-key"},
+		{name: "bare hex CKA_ID passes through", keyID: "0102030405", want: "0102030405"},
+		{name: "uri with id attribute", keyID: "pkcs11:module-path=/usr/lib/softhsm/libsofthsm2.so;slot-id=0;id=%01", want: "\x01"},
+		{name: "uri with object attribute", keyID: "pkcs11:module-path=/usr/lib/softhsm/libsofthsm2.so;slot-id=0;object=root-key", want: "root-key"},
+		{name: "uri with neither id nor object", keyID: "pkcs11:module-path=/usr/lib/softhsm/libsofthsm2.so;slot-id=0", wantErr: true},
+		{name: "malformed uri", keyID: "pkcs11:this is not valid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkcs11KeyID(tt.keyID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("pkcs11KeyID(%q) = %q, want error", tt.keyID, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pkcs11KeyID(%q) returned unexpected error: %v", tt.keyID, err)
+			}
+			if got != tt.want {
+				t.Errorf("pkcs11KeyID(%q) = %q, want %q", tt.keyID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePIVSlot(t *testing.T) {
+	wantRetired := func(raw byte) piv.Slot {
+		slot, ok := piv.RetiredKeyManagementSlot(uint32(raw))
+		if !ok {
+			t.Fatalf("test setup: no retired slot for raw byte %#x", raw)
+		}
+		return slot
+	}
+
+	tests := []struct {
+		name        string
+		keyID       string
+		want        piv.Slot
+		wantRetired bool
+		wantErr     bool
+	}{
+		{name: "authentication", keyID: "9a", want: piv.SlotAuthentication},
+		{name: "signature", keyID: "9c", want: piv.SlotSignature},
+		{name: "key management", keyID: "9d", want: piv.SlotKeyManagement},
+		{name: "card authentication", keyID: "9e", want: piv.SlotCardAuthentication},
+		{name: "case insensitive", keyID: "9C", want: piv.SlotSignature},
+		{name: "suffix after colon is ignored", keyID: "9c:touch-always", want: piv.SlotSignature},
+		{name: "first retired slot", keyID: "82", want: wantRetired(0x82), wantRetired: true},
+		{name: "last retired slot", keyID: "95", want: wantRetired(0x95), wantRetired: true},
+		{name: "mid retired slot", keyID: "8a", want: wantRetired(0x8a), wantRetired: true},
+		{name: "unknown slot", keyID: "9b", wantErr: true},
+		{name: "out of range retired slot", keyID: "96", wantErr: true},
+		{name: "not hex", keyID: "zz", wantErr: true},
+		{name: "empty", keyID: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, retired, err := parsePIVSlot(tt.keyID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePIVSlot(%q) = %v, want error", tt.keyID, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePIVSlot(%q) returned unexpected error: %v", tt.keyID, err)
+			}
+			if got != tt.want {
+				t.Errorf("parsePIVSlot(%q) = %+v, want %+v", tt.keyID, got, tt.want)
+			}
+			if retired != tt.wantRetired {
+				t.Errorf("parsePIVSlot(%q) retired = %v, want %v", tt.keyID, retired, tt.wantRetired)
+			}
+		})
+	}
+}
+
+func TestPIVTouchPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyID   string
+		want    piv.TouchPolicy
+		wantErr bool
+	}{
+		{name: "no suffix defaults to never", keyID: "9c", want: piv.TouchPolicyNever},
+		{name: "empty suffix defaults to never", keyID: "9c:", want: piv.TouchPolicyNever},
+		{name: "touch-never", keyID: "9c:touch-never", want: piv.TouchPolicyNever},
+		{name: "touch-always", keyID: "9c:touch-always", want: piv.TouchPolicyAlways},
+		{name: "touch-cached", keyID: "9c:touch-cached", want: piv.TouchPolicyCached},
+		{name: "case insensitive", keyID: "9c:TOUCH-ALWAYS", want: piv.TouchPolicyAlways},
+		{name: "unknown suffix errors", keyID: "9c:bogus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pivTouchPolicy(tt.keyID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("pivTouchPolicy(%q) = %v, want error", tt.keyID, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pivTouchPolicy(%q) returned unexpected error: %v", tt.keyID, err)
+			}
+			if got != tt.want {
+				t.Errorf("pivTouchPolicy(%q) = %v, want %v", tt.keyID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePIVManagementKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		wantErr bool
+	}{
+		{name: "valid 24-byte hex", s: strings.Repeat("ab", 24)},
+		{name: "not hex", s: strings.Repeat("zz", 24), wantErr: true},
+		{name: "too short", s: strings.Repeat("ab", 16), wantErr: true},
+		{name: "too long", s: strings.Repeat("ab", 32), wantErr: true},
+		{name: "empty", s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePIVManagementKey(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePIVManagementKey(%q) = %v, want error", tt.s, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePIVManagementKey(%q) returned unexpected error: %v", tt.s, err)
+			}
+			if len(got) != 24 {
+				t.Errorf("parsePIVManagementKey(%q) returned %d bytes, want 24", tt.s, len(got))
+			}
+		})
+	}
+}
+
+func TestAWSKeySpec(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      awskmstypes.KeySpec
+		wantErr   bool
+	}{
+		{algorithm: "ECDSA_P256", want: awskmstypes.KeySpecEccNistP256},
+		{algorithm: "ECDSA_P384", want: awskmstypes.KeySpecEccNistP384},
+		{algorithm: "RSA_3072", want: awskmstypes.KeySpecRsa3072},
+		{algorithm: "RSA_4096", want: awskmstypes.KeySpecRsa4096},
+		{algorithm: "ED25519", wantErr: true},
+		{algorithm: "bogus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			got, err := awsKeySpec(tt.algorithm)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("awsKeySpec(%q) = %v, want error", tt.algorithm, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("awsKeySpec(%q) returned unexpected error: %v", tt.algorithm, err)
+			}
+			if got != tt.want {
+				t.Errorf("awsKeySpec(%q) = %v, want %v", tt.algorithm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGCPAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm
+		wantErr   bool
+	}{
+		{algorithm: "ECDSA_P256", want: kmspb.CryptoKeyVersion_EC_SIGN_P256_SHA256},
+		{algorithm: "ECDSA_P384", want: kmspb.CryptoKeyVersion_EC_SIGN_P384_SHA384},
+		{algorithm: "RSA_3072", want: kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_3072_SHA256},
+		{algorithm: "RSA_4096", want: kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_4096_SHA256},
+		{algorithm: "ED25519", wantErr: true},
+		{algorithm: "bogus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			got, err := gcpAlgorithm(tt.algorithm)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("gcpAlgorithm(%q) = %v, want error", tt.algorithm, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("gcpAlgorithm(%q) returned unexpected error: %v", tt.algorithm, err)
+			}
+			if got != tt.want {
+				t.Errorf("gcpAlgorithm(%q) = %v, want %v", tt.algorithm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultKeyType(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      string
+		wantErr   bool
+	}{
+		{algorithm: "ECDSA_P256", want: "ecdsa-p256"},
+		{algorithm: "ECDSA_P384", want: "ecdsa-p384"},
+		{algorithm: "ED25519", want: "ed25519"},
+		{algorithm: "RSA_3072", want: "rsa-3072"},
+		{algorithm: "RSA_4096", want: "rsa-4096"},
+		{algorithm: "bogus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			got, err := vaultKeyType(tt.algorithm)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("vaultKeyType(%q) = %q, want error", tt.algorithm, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("vaultKeyType(%q) returned unexpected error: %v", tt.algorithm, err)
+			}
+			if got != tt.want {
+				t.Errorf("vaultKeyType(%q) = %q, want %q", tt.algorithm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeKeyURI(t *testing.T) {
+	t.Run("full URI is used as-is and its query string merges into opts", func(t *testing.T) {
+		config := KMSConfig{Type: "awskms", Options: map[string]string{"aws-region": "us-east-1"}}
+		scheme, uri, opts, err := normalizeKeyURI(config, "awskms:///alias/root?aws-region=us-west-2")
+		if err != nil {
+			t.Fatalf("normalizeKeyURI returned unexpected error: %v", err)
+		}
+		if scheme != "awskms" {
+			t.Errorf("scheme = %q, want %q", scheme, "awskms")
+		}
+		if uri != "awskms:///alias/root?aws-region=us-west-2" {
+			t.Errorf("uri = %q, want the input unchanged", uri)
+		}
+		if opts["aws-region"] != "us-west-2" {
+			t.Errorf("opts[aws-region] = %q, want the query string value to take precedence", opts["aws-region"])
+		}
+	})
+
+	t.Run("pkcs11 bare scheme is recognized without a :// separator", func(t *testing.T) {
+		scheme, uri, _, err := normalizeKeyURI(KMSConfig{}, "pkcs11:object=root-key")
+		if err != nil {
+			t.Fatalf("normalizeKeyURI returned unexpected error: %v", err)
+		}
+		if scheme != "pkcs11" {
+			t.Errorf("scheme = %q, want %q", scheme, "pkcs11")
+		}
+		if uri != "pkcs11:object=root-key" {
+			t.Errorf("uri = %q, want the input unchanged", uri)
+		}
+	})
+
+	t.Run("legacy shorthand expands via config.Type", func(t *testing.T) {
+		config := KMSConfig{Type: "hashivault", Options: map[string]string{"vault-token": "t"}}
+		scheme, uri, opts, err := normalizeKeyURI(config, "transit/keys/root")
+		if err != nil {
+			t.Fatalf("normalizeKeyURI returned unexpected error: %v", err)
+		}
+		if scheme != "hashivault" {
+			t.Errorf("scheme = %q, want %q", scheme, "hashivault")
+		}
+		if uri != "hashivault://transit/keys/root" {
+			t.Errorf("uri = %q, want expanded legacy shorthand", uri)
+		}
+		if opts["vault-token"] != "t" {
+			t.Errorf("opts[vault-token] = %q, want config.Options carried through", opts["vault-token"])
+		}
+	})
+
+	t.Run("empty key ID errors", func(t *testing.T) {
+		if _, _, _, err := normalizeKeyURI(KMSConfig{Type: "awskms"}, ""); err == nil {
+			t.Fatal("normalizeKeyURI(\"\") = nil error, want error")
+		}
+	})
+
+	t.Run("unsupported legacy type errors", func(t *testing.T) {
+		if _, _, _, err := normalizeKeyURI(KMSConfig{Type: "bogus"}, "some-key"); err == nil {
+			t.Fatal("normalizeKeyURI with unsupported type = nil error, want error")
+		}
+	})
+}
+
+func TestSoftKMSKeyPath(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		config KMSConfig
+		keyID  string
+		want   string
+	}{
+		{
+			name:   "relative keyID joins onto key-path directory",
+			config: KMSConfig{Options: map[string]string{"key-path": dir}},
+			keyID:  "root.pem",
+			want:   filepath.Join(dir, "root.pem"),
+		},
+		{
+			name:   "absolute keyID is left alone",
+			config: KMSConfig{Options: map[string]string{"key-path": dir}},
+			keyID:  "/etc/keys/root.pem",
+			want:   "/etc/keys/root.pem",
+		},
+		{
+			name:   "no key-path option leaves keyID alone",
+			config: KMSConfig{},
+			keyID:  "root.pem",
+			want:   "root.pem",
+		},
+		{
+			name:   "key-path that isn't a directory leaves keyID alone",
+			config: KMSConfig{Options: map[string]string{"key-path": filepath.Join(dir, "not-a-dir")}},
+			keyID:  "root.pem",
+			want:   "root.pem",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := softKMSKeyPath(tt.config, tt.keyID); got != tt.want {
+				t.Errorf("softKMSKeyPath(%+v, %q) = %q, want %q", tt.config, tt.keyID, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestSoftKMSKey generates an ECDSA P-256 key, PEM-encodes it as
+// PKCS#8, and writes it to a private (0600) file under t.TempDir().
+func writeTestSoftKMSKey(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate test key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal test key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write test key: %v", err)
+	}
+	return path
+}
+
+func TestSoftKMSProviderOpenStripsScheme(t *testing.T) {
+	keyPath := writeTestSoftKMSKey(t)
+
+	tests := []struct {
+		name string
+		uri  string
+	}{
+		{name: "bare path", uri: keyPath},
+		{name: "single-slash scheme", uri: "softkms:" + keyPath},
+		{name: "double-slash scheme", uri: "softkms://" + keyPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := softKMSProvider{}.Open(context.Background(), tt.uri, nil); err != nil {
+				t.Fatalf("softKMSProvider{}.Open(%q) error = %v, want nil", tt.uri, err)
+			}
+		})
+	}
+}
+
+func TestSoftKMSSignerVerifierRoundTrip(t *testing.T) {
+	keyPath := writeTestSoftKMSKey(t)
+	sv, err := newSoftKMSSignerVerifier(KMSConfig{Type: "softkms"}, keyPath)
+	if err != nil {
+		t.Fatalf("newSoftKMSSignerVerifier returned unexpected error: %v", err)
+	}
+
+	if _, err := sv.PublicKey(); err != nil {
+		t.Fatalf("PublicKey() returned unexpected error: %v", err)
+	}
+
+	message := "certmaker softkms round trip"
+	sig, err := sv.SignMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("SignMessage returned unexpected error: %v", err)
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), strings.NewReader(message)); err != nil {
+		t.Fatalf("VerifySignature returned unexpected error: %v", err)
+	}
+	if err := sv.VerifySignature(bytes.NewReader(sig), strings.NewReader("tampered message")); err == nil {
+		t.Fatal("VerifySignature over a tampered message = nil error, want error")
+	}
+}
+
+func TestValidateKMSConfigRejectsUnsupportedSoftKMSKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "NOT A KEY", Bytes: []byte("garbage")}), 0o600); err != nil {
+		t.Fatalf("failed to write test key: %v", err)
+	}
+	config := KMSConfig{Type: "softkms", RootKeyID: path, LeafKeyID: path}
+	if err := ValidateKMSConfig(config); err == nil {
+		t.Fatal("ValidateKMSConfig with an unparseable key = nil error, want error")
+	}
+}